@@ -0,0 +1,289 @@
+// Command idbench is a small dedicated load tester for the /id endpoint: it
+// drives a configurable concurrency/QPS profile against a running server and
+// reports latency percentiles plus ID correctness, without having to write
+// bespoke test code for every -step/Mongo-latency combination.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// maxSeenIDs 限制 verify=unique 模式下缓存的已见ID数量，超出后按先进先出
+// 淘汰最旧的ID，避免长时间或高 -n 压测把进程内存撑爆。
+const maxSeenIDs = 1_000_000
+
+type verifyMode string
+
+const (
+	verifyNone      verifyMode = "none"
+	verifyUnique    verifyMode = "unique"
+	verifyMonotonic verifyMode = "monotonic"
+)
+
+type result struct {
+	latency    time.Duration
+	statusCode int
+	err        error
+	id         int64
+	hasID      bool
+}
+
+func main() {
+	url := flag.String("url", "http://localhost:8080/id", "Target URL to hit")
+	concurrency := flag.Int("c", 10, "Number of concurrent workers")
+	totalRequests := flag.Int("n", 0, "Total number of requests to send (ignored if -d is set)")
+	duration := flag.Duration("d", 0, "How long to run for (overrides -n)")
+	rps := flag.Float64("rps", 0, "Target aggregate requests/sec, token-bucket paced (0 = unlimited)")
+	verify := flag.String("verify", "none", "Verification mode: unique|monotonic|none")
+	timeout := flag.Duration("timeout", 5*time.Second, "Per-request HTTP timeout")
+	flag.Parse()
+
+	mode := verifyMode(*verify)
+	switch mode {
+	case verifyNone, verifyUnique, verifyMonotonic:
+	default:
+		log.Fatalf("Unknown -verify %q, expected unique, monotonic or none", *verify)
+	}
+
+	if *duration <= 0 && *totalRequests <= 0 {
+		log.Fatalf("Either -n or -d must be set")
+	}
+
+	var limiter *rate.Limiter
+	if *rps > 0 {
+		limiter = rate.NewLimiter(rate.Limit(*rps), max(1, int(*rps)))
+	}
+
+	client := &http.Client{Timeout: *timeout}
+
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	results := make(chan result, *concurrency*2)
+
+	var sent atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if *duration <= 0 && sent.Add(1) > int64(*totalRequests) {
+					return
+				}
+				if limiter != nil {
+					if err := limiter.Wait(ctx); err != nil {
+						return
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+
+				results <- doRequest(client, *url)
+
+				if *duration > 0 && ctx.Err() != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report := newReport(mode)
+	for r := range results {
+		report.add(r)
+	}
+	elapsed := time.Since(start)
+
+	report.print(elapsed)
+}
+
+func doRequest(client *http.Client, url string) result {
+	reqStart := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return result{latency: time.Since(reqStart), err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	latency := time.Since(reqStart)
+	if err != nil {
+		return result{latency: latency, statusCode: resp.StatusCode, err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return result{latency: latency, statusCode: resp.StatusCode, err: fmt.Errorf("%s", string(body))}
+	}
+
+	var decoded struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return result{latency: latency, statusCode: resp.StatusCode, err: fmt.Errorf("parse id failed: %v", err)}
+	}
+
+	return result{latency: latency, statusCode: resp.StatusCode, id: decoded.ID, hasID: true}
+}
+
+// report 汇总一次压测运行的延迟分布、错误分布和正确性校验结果。
+type report struct {
+	mode verifyMode
+
+	mu          sync.Mutex
+	latencies   []time.Duration
+	errByStatus map[int]int
+	otherErrors int
+
+	seen          map[int64]struct{} // verify=unique 时记录已出现过的ID，容量受 maxSeenIDs 限制
+	seenOrder     []int64            // 按插入顺序记录seen中的ID，用于淘汰最旧的一批
+	seenHead      int                // seenOrder中下一个将被淘汰的位置（环形缓冲区）
+	uniqueChecked int                // verify=unique 时累计检查过的ID总数，不受淘汰影响
+	duplicates    int
+	maxSeen       int64
+	outOfOrder    int // verify=monotonic 时，收到的ID小于已见过的最大值的次数
+	succeeded     int
+}
+
+func newReport(mode verifyMode) *report {
+	r := &report{
+		mode:        mode,
+		errByStatus: make(map[int]int),
+	}
+	if mode == verifyUnique {
+		r.seen = make(map[int64]struct{}, maxSeenIDs)
+		r.seenOrder = make([]int64, 0, maxSeenIDs)
+	}
+	return r
+}
+
+// recordSeen 把id计入seen集合，集合达到maxSeenIDs上限后淘汰最旧的一个，
+// 令verify=unique在长时间/高-n压测下的内存占用保持有界。
+func (r *report) recordSeen(id int64) {
+	if len(r.seenOrder) < maxSeenIDs {
+		r.seenOrder = append(r.seenOrder, id)
+		r.seen[id] = struct{}{}
+		return
+	}
+
+	evicted := r.seenOrder[r.seenHead]
+	delete(r.seen, evicted)
+	r.seenOrder[r.seenHead] = id
+	r.seen[id] = struct{}{}
+	r.seenHead = (r.seenHead + 1) % maxSeenIDs
+}
+
+func (r *report) add(res result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies = append(r.latencies, res.latency)
+
+	if res.err != nil {
+		if res.statusCode != 0 {
+			r.errByStatus[res.statusCode]++
+		} else {
+			r.otherErrors++
+		}
+		return
+	}
+
+	r.succeeded++
+
+	if !res.hasID {
+		return
+	}
+
+	switch r.mode {
+	case verifyUnique:
+		r.uniqueChecked++
+		if _, ok := r.seen[res.id]; ok {
+			r.duplicates++
+		} else {
+			r.recordSeen(res.id)
+		}
+	case verifyMonotonic:
+		if res.id < r.maxSeen {
+			r.outOfOrder++
+		}
+		if res.id > r.maxSeen {
+			r.maxSeen = res.id
+		}
+	}
+}
+
+func (r *report) print(elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+
+	total := len(r.latencies)
+	fmt.Printf("Requests:     %d in %s\n", total, elapsed)
+	if elapsed > 0 {
+		fmt.Printf("Throughput:   %.2f ids/sec\n", float64(r.succeeded)/elapsed.Seconds())
+	}
+	fmt.Printf("Succeeded:    %d\n", r.succeeded)
+	fmt.Printf("Latency p50:  %s\n", percentile(r.latencies, 50))
+	fmt.Printf("Latency p90:  %s\n", percentile(r.latencies, 90))
+	fmt.Printf("Latency p99:  %s\n", percentile(r.latencies, 99))
+	fmt.Printf("Latency p999: %s\n", percentile(r.latencies, 99.9))
+
+	if len(r.errByStatus) > 0 || r.otherErrors > 0 {
+		fmt.Println("Errors by status code:")
+		for code, count := range r.errByStatus {
+			fmt.Printf("  %d: %d\n", code, count)
+		}
+		if r.otherErrors > 0 {
+			fmt.Printf("  (no response): %d\n", r.otherErrors)
+		}
+	}
+
+	switch r.mode {
+	case verifyUnique:
+		fmt.Printf("Unique check: %d duplicate IDs out of %d (last %d tracked for dedup)\n", r.duplicates, r.uniqueChecked, len(r.seen))
+	case verifyMonotonic:
+		fmt.Printf("Monotonic check: %d IDs received out of increasing order (max seen: %d)\n", r.outOfOrder, r.maxSeen)
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p / 100 * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// IDSource 是 /id、/batch 的 handler 依赖的最小接口。MultiTenantGenerator 和
+// SnowflakeIDGenerator 都实现了它。
+type IDSource interface {
+	NextIDCtx(ctx context.Context, bizTag string) (int64, error)
+}
+
+// batchResponse 是 /batch 统一的JSON响应体。
+type batchResponse struct {
+	IDs   []int64 `json:"ids"`
+	Count int     `json:"count"`
+}
+
+// IDHandler 返回 /id 的处理函数：生成单个ID并以 {"id":N} 的JSON格式返回，
+// 请求被客户端取消时通过 r.Context() 级联取消底层的 NextID 调用。
+func IDHandler(source IDSource, defaultBizTag string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		id, err := source.NextIDCtx(r.Context(), bizTagOrDefault(r, defaultBizTag))
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]int64{"id": id})
+	}
+}
+
+// BatchHandler 返回 /batch 的处理函数：批量生成ID并以 {"ids":[...],"count":N}
+// 的统一JSON格式返回。
+func BatchHandler(source IDSource, defaultBizTag string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		count, err := strconv.Atoi(r.URL.Query().Get("count"))
+		if err != nil || count <= 0 || count > 1000 {
+			count = 1
+		}
+
+		bizTag := bizTagOrDefault(r, defaultBizTag)
+		ctx := r.Context()
+		ids := make([]int64, 0, count)
+		for i := 0; i < count; i++ {
+			if err := ctx.Err(); err != nil {
+				writeJSONError(w, http.StatusRequestTimeout, err.Error())
+				return
+			}
+
+			id, err := source.NextIDCtx(ctx, bizTag)
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			ids = append(ids, id)
+		}
+
+		writeJSON(w, http.StatusOK, batchResponse{IDs: ids, Count: len(ids)})
+	}
+}
+
+func bizTagOrDefault(r *http.Request, defaultBizTag string) string {
+	if biz := r.URL.Query().Get("biz"); biz != "" {
+		return biz
+	}
+	return defaultBizTag
+}
+
+// HealthzHandler 是基础存活检查：只要进程能处理HTTP请求就返回200，不检查任何
+// 依赖是否就绪。
+func HealthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
+
+// ReadyChecker 返回nil表示已经准备好对外发号，用于 /readyz。
+type ReadyChecker func(ctx context.Context) error
+
+// ReadyzHandler 返回 /readyz 的处理函数，ready返回错误时视为未就绪，返回503。
+func ReadyzHandler(ready ReadyChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(r.Context()); err != nil {
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	}
+}
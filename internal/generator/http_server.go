@@ -5,13 +5,17 @@ import (
 	"net/http"
 )
 
+// IDServer 通过 MultiTenantGenerator 按请求携带的 bizTag 发号，
+// 未携带 biz 参数时回退到 defaultBizTag。
 type IDServer struct {
-	generator *SegmentIDGenerator
+	generator     *MultiTenantGenerator
+	defaultBizTag string
 }
 
-func NewIDServer(generator *SegmentIDGenerator) *IDServer {
+func NewIDServer(generator *MultiTenantGenerator, defaultBizTag string) *IDServer {
 	return &IDServer{
-		generator: generator,
+		generator:     generator,
+		defaultBizTag: defaultBizTag,
 	}
 }
 
@@ -21,7 +25,12 @@ func (s *IDServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := s.generator.NextID()
+	bizTag := r.URL.Query().Get("biz")
+	if bizTag == "" {
+		bizTag = s.defaultBizTag
+	}
+
+	id, err := s.generator.NextIDCtx(r.Context(), bizTag)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
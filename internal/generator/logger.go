@@ -0,0 +1,38 @@
+package generator
+
+import "go.uber.org/zap"
+
+// Logger 是发号器用来上报状态变化的最小日志接口，调用方可以注入自己的实现
+// 来对接不同的日志系统，默认实现基于 zap。
+type Logger interface {
+	Infow(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// zapLogger 用 zap.SugaredLogger 实现 Logger。
+type zapLogger struct {
+	sugar *zap.SugaredLogger
+}
+
+// NewZapLogger 返回一个基于 zap 生产环境配置的默认 Logger。
+func NewZapLogger() (Logger, error) {
+	l, err := zap.NewProduction()
+	if err != nil {
+		return nil, err
+	}
+	return &zapLogger{sugar: l.Sugar()}, nil
+}
+
+func (z *zapLogger) Infow(msg string, keysAndValues ...interface{}) {
+	z.sugar.Infow(msg, keysAndValues...)
+}
+
+func (z *zapLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	z.sugar.Errorw(msg, keysAndValues...)
+}
+
+// noopLogger 是没有注入 Logger 时的默认实现，避免在各处判空。
+type noopLogger struct{}
+
+func (noopLogger) Infow(string, ...interface{})  {}
+func (noopLogger) Errorw(string, ...interface{}) {}
@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// clientLimiterIdleTTL 是客户端限流器在没有新请求后保留的时长，超过这个时长
+// 未被访问就会被清理，避免公网流量下 limiters 随不同来源IP无限增长。
+const clientLimiterIdleTTL = 10 * time.Minute
+
+// clientLimiterSweepInterval 是清理空闲限流器的巡检周期。
+const clientLimiterSweepInterval = time.Minute
+
+// clientLimiter 按客户端IP懒加载并缓存独立的令牌桶限流器，并定期淘汰长时间
+// 没有请求的客户端。
+type clientLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*limiterEntry
+	rps      rate.Limit
+	burst    int
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newClientLimiter(rps float64, burst int) *clientLimiter {
+	c := &clientLimiter{
+		limiters: make(map[string]*limiterEntry),
+		rps:      rate.Limit(rps),
+		burst:    burst,
+	}
+	go c.sweepIdle()
+	return c
+}
+
+func (c *clientLimiter) get(key string) *rate.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.limiters[key]
+	if !ok {
+		e = &limiterEntry{limiter: rate.NewLimiter(c.rps, c.burst)}
+		c.limiters[key] = e
+	}
+	e.lastSeen = time.Now()
+	return e.limiter
+}
+
+// sweepIdle 周期性清理超过 clientLimiterIdleTTL 没有请求的客户端限流器。
+func (c *clientLimiter) sweepIdle() {
+	ticker := time.NewTicker(clientLimiterSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.evictIdle(time.Now())
+	}
+}
+
+// evictIdle 删除 now 之前 clientLimiterIdleTTL 内都没有被访问过的限流器。
+func (c *clientLimiter) evictIdle(now time.Time) {
+	cutoff := now.Add(-clientLimiterIdleTTL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.limiters {
+		if e.lastSeen.Before(cutoff) {
+			delete(c.limiters, key)
+		}
+	}
+}
+
+// RateLimitMiddleware 对每个客户端IP套用独立的令牌桶限流，超出配额的请求
+// 收到 429。
+func RateLimitMiddleware(rps float64, burst int) func(http.Handler) http.Handler {
+	limiter := newClientLimiter(rps, burst)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !limiter.get(clientIP(r)).Allow() {
+				writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ConcurrencyLimitMiddleware 用一个全局信号量限制同时处理的请求数，防止号段
+// 刷新期间大量并发请求把后端存储打垮。
+func ConcurrencyLimitMiddleware(max int) func(http.Handler) http.Handler {
+	sem := make(chan struct{}, max)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				next.ServeHTTP(w, r)
+			default:
+				writeJSONError(w, http.StatusServiceUnavailable, "server is at capacity")
+			}
+		})
+	}
+}
+
+// Chain 按顺序把 middlewares 套在 h 外层，列表中第一个 middleware 最先执行。
+func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
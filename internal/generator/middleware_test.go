@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClientLimiterReturnsSameLimiterForSameKey(t *testing.T) {
+	c := newClientLimiter(10, 20)
+
+	l1 := c.get("1.2.3.4")
+	l2 := c.get("1.2.3.4")
+	if l1 != l2 {
+		t.Fatal("期望同一个客户端复用同一个限流器")
+	}
+}
+
+func TestClientLimiterIsolatesDifferentKeys(t *testing.T) {
+	c := newClientLimiter(10, 20)
+
+	l1 := c.get("1.2.3.4")
+	l2 := c.get("5.6.7.8")
+	if l1 == l2 {
+		t.Fatal("期望不同客户端拥有独立的限流器")
+	}
+}
+
+func TestClientLimiterBurst(t *testing.T) {
+	c := newClientLimiter(1, 2)
+
+	l := c.get("1.2.3.4")
+	if !l.Allow() {
+		t.Fatal("期望burst容量内的第1个请求被放行")
+	}
+	if !l.Allow() {
+		t.Fatal("期望burst容量内的第2个请求被放行")
+	}
+	if l.Allow() {
+		t.Fatal("期望超出burst容量的第3个请求被拒绝")
+	}
+}
+
+func TestClientLimiterEvictIdleRemovesStaleEntries(t *testing.T) {
+	c := newClientLimiter(10, 20)
+	c.get("stale")
+	c.get("fresh")
+
+	now := time.Now()
+	c.mu.Lock()
+	c.limiters["stale"].lastSeen = now.Add(-2 * clientLimiterIdleTTL)
+	c.mu.Unlock()
+
+	c.evictIdle(now)
+
+	c.mu.Lock()
+	_, staleStillThere := c.limiters["stale"]
+	_, freshStillThere := c.limiters["fresh"]
+	c.mu.Unlock()
+
+	if staleStillThere {
+		t.Error("期望长时间空闲的限流器被清理")
+	}
+	if !freshStillThere {
+		t.Error("期望仍在TTL内的限流器被保留")
+	}
+}
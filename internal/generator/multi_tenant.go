@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/haoxianhan/id-generator/internal/generator/store"
+	"github.com/haoxianhan/id-generator/internal/stats"
+)
+
+// MultiTenantGenerator 按 bizTag 懒加载并缓存多个 SegmentIDGenerator，
+// 使单个进程可以同时为多个业务线发号，每个 bizTag 拥有独立的号段和加载协程。
+type MultiTenantGenerator struct {
+	// newStore 为 bizTag 构造对应的 SegmentStore，调用方可以让它对每个
+	// bizTag 返回同一个共享的 store（Mongo/Redis/SQL 连接通常都这样复用），
+	// 也可以按 bizTag 路由到不同的实例以便按租户分片存储。
+	newStore func(bizTag string) (store.SegmentStore, error)
+
+	allowedTags map[string]struct{} // 为 nil 时不做限制
+	generators  sync.Map            // bizTag -> *SegmentIDGenerator
+	createMu    sync.Mutex          // 避免同一个 bizTag 被并发重复创建
+
+	stats  *stats.Stats // 所有 bizTag 共享的计数器，统一暴露在一份 /metrics 上
+	logger Logger
+}
+
+// NewMultiTenantGenerator 创建一个多租户发号器，newStore 决定每个 bizTag 使用
+// 哪个 SegmentStore 后端（Mongo/Redis/SQL 均可，见 NewMongoMultiTenantGenerator
+// 的便捷用法）。allowedTags 为空时不限制可用的 bizTag，否则只有在白名单内的
+// bizTag 才会被懒加载，防止调用方无限制地创建号段。
+func NewMultiTenantGenerator(newStore func(bizTag string) (store.SegmentStore, error), allowedTags []string) *MultiTenantGenerator {
+	m := &MultiTenantGenerator{
+		newStore: newStore,
+		stats:    stats.New(),
+		logger:   noopLogger{},
+	}
+
+	if len(allowedTags) > 0 {
+		m.allowedTags = make(map[string]struct{}, len(allowedTags))
+		for _, tag := range allowedTags {
+			m.allowedTags[tag] = struct{}{}
+		}
+	}
+
+	return m
+}
+
+// NewMongoMultiTenantGenerator 是基于 MongoDB 的便捷构造函数：所有 bizTag
+// 共享同一个 Mongo 连接，_id 仍然是 bizTag 本身。需要 Redis/SQL 等其他后端，
+// 或者按 bizTag 分片存储时，请直接用 NewMultiTenantGenerator 传入自定义的
+// newStore。
+func NewMongoMultiTenantGenerator(mongoURI string, allowedTags []string) (*MultiTenantGenerator, error) {
+	mongoStore, err := store.NewMongoStore(mongoURI, "test", "segments")
+	if err != nil {
+		return nil, err
+	}
+	return NewMultiTenantGenerator(func(string) (store.SegmentStore, error) {
+		return mongoStore, nil
+	}, allowedTags), nil
+}
+
+// SetLogger 注入一个结构化 Logger，供之后创建的每个 bizTag 对应的
+// SegmentIDGenerator 使用。必须在 Get/NextID 之前调用才能覆盖默认 bizTag。
+func (m *MultiTenantGenerator) SetLogger(logger Logger) {
+	m.logger = logger
+}
+
+// Stats 返回所有 bizTag 共享的运行时计数器。
+func (m *MultiTenantGenerator) Stats() *stats.Stats {
+	return m.stats
+}
+
+// Get 返回 bizTag 对应的 SegmentIDGenerator，不存在时按需创建。
+func (m *MultiTenantGenerator) Get(bizTag string) (*SegmentIDGenerator, error) {
+	if v, ok := m.generators.Load(bizTag); ok {
+		return v.(*SegmentIDGenerator), nil
+	}
+
+	if m.allowedTags != nil {
+		if _, ok := m.allowedTags[bizTag]; !ok {
+			return nil, fmt.Errorf("bizTag %q is not in the allow-list", bizTag)
+		}
+	}
+
+	m.createMu.Lock()
+	defer m.createMu.Unlock()
+
+	// 加锁后再检查一次，避免并发请求重复创建
+	if v, ok := m.generators.Load(bizTag); ok {
+		return v.(*SegmentIDGenerator), nil
+	}
+
+	segStore, err := m.newStore(bizTag)
+	if err != nil {
+		return nil, fmt.Errorf("create store for bizTag %q failed: %v", bizTag, err)
+	}
+
+	gen, err := NewSegmentIDGeneratorWithStore(segStore, bizTag, WithStats(m.stats), WithLogger(m.logger))
+	if err != nil {
+		return nil, fmt.Errorf("create generator for bizTag %q failed: %v", bizTag, err)
+	}
+
+	m.generators.Store(bizTag, gen)
+	return gen, nil
+}
+
+// NextID 为指定 bizTag 生成下一个ID。
+func (m *MultiTenantGenerator) NextID(bizTag string) (int64, error) {
+	return m.NextIDCtx(context.Background(), bizTag)
+}
+
+// NextIDCtx 为指定 bizTag 生成下一个ID，ctx 取消时请求会尽快放弃。实现了
+// IDSource，可直接交给 IDHandler/BatchHandler 使用。
+func (m *MultiTenantGenerator) NextIDCtx(ctx context.Context, bizTag string) (int64, error) {
+	gen, err := m.Get(bizTag)
+	if err != nil {
+		return 0, err
+	}
+	return gen.NextIDCtx(ctx)
+}
+
+// Ready 报告指定 bizTag 是否已就绪（已有可用号段且底层存储可达）。
+func (m *MultiTenantGenerator) Ready(ctx context.Context, bizTag string) error {
+	gen, err := m.Get(bizTag)
+	if err != nil {
+		return err
+	}
+	return gen.Ready(ctx)
+}
+
+// Close 关闭所有已创建的 SegmentIDGenerator。
+func (m *MultiTenantGenerator) Close() error {
+	var firstErr error
+	m.generators.Range(func(_, value interface{}) bool {
+		if err := value.(*SegmentIDGenerator).Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return true
+	})
+	return firstErr
+}
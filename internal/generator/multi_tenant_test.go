@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/haoxianhan/id-generator/internal/generator/store"
+)
+
+func unusedStoreFactory(string) (store.SegmentStore, error) {
+	return nil, errors.New("store factory should not be called")
+}
+
+func TestMultiTenantGeneratorAllowListRejectsUnknownTag(t *testing.T) {
+	m := NewMultiTenantGenerator(unusedStoreFactory, []string{"order", "payment"})
+
+	if _, err := m.Get("not-allowed"); err == nil {
+		t.Fatal("期望不在白名单内的bizTag被拒绝，实际没有返回错误")
+	}
+}
+
+func TestMultiTenantGeneratorAllowListAcceptsListedTag(t *testing.T) {
+	m := NewMultiTenantGenerator(unusedStoreFactory, []string{"order", "payment"})
+
+	if _, ok := m.allowedTags["order"]; !ok {
+		t.Fatal("期望order在白名单内")
+	}
+	if _, ok := m.allowedTags["not-allowed"]; ok {
+		t.Fatal("期望not-allowed不在白名单内")
+	}
+}
+
+func TestMultiTenantGeneratorNoAllowListConfigured(t *testing.T) {
+	m := NewMultiTenantGenerator(unusedStoreFactory, nil)
+
+	if m.allowedTags != nil {
+		t.Fatalf("没有配置白名单时 allowedTags 应该为nil，实际为%v", m.allowedTags)
+	}
+}
@@ -6,9 +6,8 @@ import (
 	"sync/atomic"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"github.com/haoxianhan/id-generator/internal/generator/store"
+	"github.com/haoxianhan/id-generator/internal/stats"
 )
 
 type Segment struct {
@@ -22,24 +21,53 @@ type SegmentIDGenerator struct {
 	nextSegment    atomic.Pointer[Segment] // 下一个号段
 	bizTag         string
 	step           int32
-	mongoClient    *mongo.Client
+	store          store.SegmentStore
 	loadingFlag    atomic.Bool
+
+	stats  *stats.Stats
+	logger Logger
+}
+
+// Option 定制 SegmentIDGenerator 的可选依赖，例如注入共享的 Stats 或 Logger。
+type Option func(*SegmentIDGenerator)
+
+// WithStats 让多个 SegmentIDGenerator（例如同一进程内不同 bizTag）共享同一个
+// Stats 实例，以便统一暴露 /metrics。不指定时每个实例使用独立的 Stats。
+func WithStats(s *stats.Stats) Option {
+	return func(g *SegmentIDGenerator) { g.stats = s }
 }
 
-func NewSegmentIDGenerator(mongoURI, bizTag string) (*SegmentIDGenerator, error) {
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(mongoURI))
+// WithLogger 注入一个结构化 Logger，不指定时不输出任何状态变化日志。
+func WithLogger(logger Logger) Option {
+	return func(g *SegmentIDGenerator) { g.logger = logger }
+}
+
+// NewSegmentIDGenerator 是基于 MongoDB 的便捷构造函数，保留给历史调用方使用。
+// 需要接入其他存储后端时请使用 NewSegmentIDGeneratorWithStore。
+func NewSegmentIDGenerator(mongoURI, bizTag string, opts ...Option) (*SegmentIDGenerator, error) {
+	mongoStore, err := store.NewMongoStore(mongoURI, "test", "segments")
 	if err != nil {
-		return nil, fmt.Errorf("connect mongodb failed: %v", err)
+		return nil, err
 	}
+	return NewSegmentIDGeneratorWithStore(mongoStore, bizTag, opts...)
+}
 
+// NewSegmentIDGeneratorWithStore 用任意 SegmentStore 实现创建一个号段发号器。
+func NewSegmentIDGeneratorWithStore(segmentStore store.SegmentStore, bizTag string, opts ...Option) (*SegmentIDGenerator, error) {
 	generator := &SegmentIDGenerator{
-		bizTag:      bizTag,
-		mongoClient: client,
-		step:        1000,
+		bizTag: bizTag,
+		store:  segmentStore,
+		step:   1000,
+		stats:  stats.New(),
+		logger: noopLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(generator)
 	}
 
 	// 初始化时加载第一个号段作为当前号段
-	if err := generator.loadNextSegment(); err != nil {
+	if err := generator.loadNextSegment(context.Background()); err != nil {
 		return nil, err
 	}
 
@@ -50,8 +78,24 @@ func NewSegmentIDGenerator(mongoURI, bizTag string) (*SegmentIDGenerator, error)
 	return generator, nil
 }
 
+// Stats 返回本实例的运行时计数器，可用于搭建 /metrics 或周期性快照日志。
+func (g *SegmentIDGenerator) Stats() *stats.Stats {
+	return g.stats
+}
+
+// NextID 生成下一个ID，等价于 NextIDCtx(context.Background())。
 func (g *SegmentIDGenerator) NextID() (int64, error) {
+	return g.NextIDCtx(context.Background())
+}
+
+// NextIDCtx 生成下一个ID，ctx 取消时会尽快放弃正在进行的号段切换/加载
+// （但已经从当前号段里分配出去的序号不会被撤回）。
+func (g *SegmentIDGenerator) NextIDCtx(ctx context.Context) (int64, error) {
 	for i := 0; i < 2; i++ { // 最多重试一次
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
 		current := g.currentSegment.Load()
 		if current == nil {
 			return 0, fmt.Errorf("no available segment")
@@ -59,15 +103,17 @@ func (g *SegmentIDGenerator) NextID() (int64, error) {
 
 		id := atomic.AddInt64(&current.Current, 1)
 		if id <= current.Max {
-			// 检查是否需要预加载下一个号段
+			g.stats.SetUtilization(g.bizTag, id-current.Min+1, current.Max-current.Min+1)
+			// 检查是否需要预加载下一个号段，预加载用独立的 context，不随本次请求取消
 			if g.shouldLoadNext(current) {
-				go g.loadNextSegment()
+				go g.loadNextSegment(context.Background())
 			}
+			g.stats.RecordIDIssued()
 			return id, nil
 		}
 
 		// 超出范围，切换号段
-		if err := g.switchSegment(); err != nil {
+		if err := g.switchSegment(ctx); err != nil {
 			return 0, fmt.Errorf("failed to switch segment: %v", err)
 		}
 	}
@@ -88,16 +134,20 @@ func (g *SegmentIDGenerator) shouldLoadNext(segment *Segment) bool {
 		!g.loadingFlag.Load()
 }
 
-func (g *SegmentIDGenerator) switchSegment() error {
+func (g *SegmentIDGenerator) switchSegment(ctx context.Context) error {
+	g.logger.Infow("switching segment", "bizTag", g.bizTag)
+
 	maxRetries := 3
 	for i := 0; i < maxRetries; i++ {
 		if g.nextSegment.Load() != nil {
 			break
 		}
-		if err := g.loadNextSegment(); err != nil {
+		if err := g.loadNextSegment(ctx); err != nil {
 			if i == maxRetries-1 {
+				g.logger.Errorw("switch segment failed", "bizTag", g.bizTag, "retries", maxRetries, "error", err)
 				return fmt.Errorf("switch segment failed after %d retries: %v", maxRetries, err)
 			}
+			g.stats.RecordSwitchRetry()
 			time.Sleep(time.Millisecond * 100 * time.Duration(i+1))
 			continue
 		}
@@ -112,61 +162,45 @@ func (g *SegmentIDGenerator) switchSegment() error {
 	return nil
 }
 
-func (g *SegmentIDGenerator) loadNextSegment() error {
+func (g *SegmentIDGenerator) loadNextSegment(ctx context.Context) error {
 	if !g.loadingFlag.CompareAndSwap(false, true) {
 		return nil
 	}
 	defer g.loadingFlag.Store(false)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	loadCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": g.bizTag}
-	update := bson.M{
-		"$inc": bson.M{
-			"maxId": g.step,
-		},
-		"$setOnInsert": bson.M{
-			"initTime": time.Now(),
-		},
-	}
-
-	var result struct {
-		MaxID int64 `bson:"maxId"`
-	}
-
-	coll := g.mongoClient.Database("test").Collection("segments")
-	err := coll.FindOneAndUpdate(
-		ctx,
-		filter,
-		update,
-		options.FindOneAndUpdate().
-			SetReturnDocument(options.After).
-			SetUpsert(true),
-	).Decode(&result)
-
+	start := time.Now()
+	maxID, err := g.store.FetchAndBump(loadCtx, g.bizTag, g.step)
+	g.stats.RecordSegmentLoad(time.Since(start), err)
 	if err != nil {
-		return fmt.Errorf("load segment failed: %v", err)
-	}
-
-	// 验证返回值的合法性
-	if result.MaxID <= 0 {
-		return fmt.Errorf("invalid maxId: %d", result.MaxID)
+		g.logger.Errorw("load segment failed", "bizTag", g.bizTag, "error", err)
+		return err
 	}
 
 	newSegment := &Segment{
-		Min:     result.MaxID - int64(g.step) + 1,
-		Max:     result.MaxID,
-		Current: result.MaxID - int64(g.step),
+		Min:     maxID - int64(g.step) + 1,
+		Max:     maxID,
+		Current: maxID - int64(g.step),
 	}
 
 	g.nextSegment.Store(newSegment)
+	g.logger.Infow("segment loaded", "bizTag", g.bizTag, "min", newSegment.Min, "max", newSegment.Max)
 	return nil
 }
 
+// Ready 报告该 bizTag 是否已经有可用号段且底层存储可达，供 /readyz 使用。
+func (g *SegmentIDGenerator) Ready(ctx context.Context) error {
+	if g.currentSegment.Load() == nil {
+		return fmt.Errorf("no segment loaded yet for bizTag %q", g.bizTag)
+	}
+	return g.store.Ping(ctx)
+}
+
 func (g *SegmentIDGenerator) Close() error {
-	if g.mongoClient != nil {
-		return g.mongoClient.Disconnect(context.Background())
+	if g.store != nil {
+		return g.store.Close()
 	}
 	return nil
 }
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnowflakeIDGeneratorClockBackwardRejected(t *testing.T) {
+	g, err := NewSnowflakeIDGenerator(1)
+	if err != nil {
+		t.Fatalf("创建Snowflake生成器失败: %v", err)
+	}
+
+	// 模拟系统时钟回拨超过容忍度：把 lastMs 设置到明显领先于当前时间的位置
+	g.lastMs = time.Now().UnixMilli() + maxClockBackwardToleranceMs + 100
+
+	if _, err := g.NextID(); err == nil {
+		t.Fatal("期望时钟回拨超过容忍度时返回错误，实际没有返回错误")
+	}
+}
+
+func TestSnowflakeIDGeneratorClockBackwardWithinToleranceWaits(t *testing.T) {
+	g, err := NewSnowflakeIDGenerator(1)
+	if err != nil {
+		t.Fatalf("创建Snowflake生成器失败: %v", err)
+	}
+
+	// 回拨幅度在容忍范围内，NextID 应该自旋等到追上 lastMs 再发号，而不是报错
+	g.lastMs = time.Now().UnixMilli() + maxClockBackwardToleranceMs
+
+	id, err := g.NextID()
+	if err != nil {
+		t.Fatalf("回拨在容忍范围内时不应该报错: %v", err)
+	}
+	if id <= 0 {
+		t.Fatalf("期望生成一个正数ID，实际得到 %d", id)
+	}
+}
+
+func TestSnowflakeIDGeneratorSequenceRolloverAdvancesMs(t *testing.T) {
+	g, err := NewSnowflakeIDGenerator(1)
+	if err != nil {
+		t.Fatalf("创建Snowflake生成器失败: %v", err)
+	}
+
+	// 模拟同一毫秒内序列号已经用尽，下一次调用必须自旋到下一毫秒，
+	// 而不是复用序列号0产生重复ID
+	startMs := time.Now().UnixMilli()
+	g.lastMs = startMs
+	g.sequence = maxSequence
+
+	if _, err := g.NextID(); err != nil {
+		t.Fatalf("序列号溢出时不应该报错: %v", err)
+	}
+
+	if g.lastMs <= startMs {
+		t.Fatalf("期望序列号溢出后 lastMs 前进到下一毫秒，实际仍停留在 %d", g.lastMs)
+	}
+	if g.sequence != 0 {
+		t.Fatalf("期望进入新的毫秒后序列号从0开始，实际为 %d", g.sequence)
+	}
+}
+
+func TestSnowflakeIDGeneratorLeaseLostRefusesToGenerate(t *testing.T) {
+	g, err := NewSnowflakeIDGenerator(1)
+	if err != nil {
+		t.Fatalf("创建Snowflake生成器失败: %v", err)
+	}
+
+	lost := make(chan struct{})
+	close(lost)
+	g.leaseLost = lost
+
+	if _, err := g.NextID(); err == nil {
+		t.Fatal("期望租约丢失后拒绝发号，实际没有返回错误")
+	}
+	if err := g.Ready(nil); err == nil {
+		t.Fatal("期望租约丢失后 Ready 返回不就绪，实际返回nil")
+	}
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore 是基于 MongoDB 的 SegmentStore 实现，号段记录保存在
+// database.collection 中，_id 为 bizTag。同一个 MongoStore 通常会被
+// MultiTenantGenerator 里多个 bizTag 共享，所以 Close 做了幂等处理。
+type MongoStore struct {
+	client     *mongo.Client
+	database   string
+	collection string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewMongoStore 连接到 mongoURI 并返回一个 MongoStore。
+func NewMongoStore(mongoURI, database, collection string) (*MongoStore, error) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("connect mongodb failed: %v", err)
+	}
+
+	return &MongoStore{
+		client:     client,
+		database:   database,
+		collection: collection,
+	}, nil
+}
+
+func (s *MongoStore) FetchAndBump(ctx context.Context, bizTag string, step int32) (int64, error) {
+	// _id 沿用 bizTag 作为主键，tenantId 冗余一份同样的值方便按租户
+	// 对集合做分片/建索引
+	filter := bson.M{"_id": bizTag}
+	update := bson.M{
+		"$inc": bson.M{
+			"maxId": step,
+		},
+		"$setOnInsert": bson.M{
+			"initTime": time.Now(),
+			"tenantId": bizTag,
+		},
+	}
+
+	var result struct {
+		MaxID int64 `bson:"maxId"`
+	}
+
+	coll := s.client.Database(s.database).Collection(s.collection)
+	err := coll.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().
+			SetReturnDocument(options.After).
+			SetUpsert(true),
+	).Decode(&result)
+
+	if err != nil {
+		return 0, fmt.Errorf("load segment failed: %v", err)
+	}
+
+	if result.MaxID <= 0 {
+		return 0, fmt.Errorf("invalid maxId: %d", result.MaxID)
+	}
+
+	return result.MaxID, nil
+}
+
+func (s *MongoStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, nil)
+}
+
+func (s *MongoStore) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.client.Disconnect(context.Background())
+	})
+	return s.closeErr
+}
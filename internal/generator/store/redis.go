@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// bumpScript 用 Lua 原子地把 bizTag 对应的 key 加上 step 并返回新值，避免
+// "INCRBY 后再写入元数据"这类组合操作被其他客户端的请求打断。
+var bumpScript = redis.NewScript(`
+local maxId = redis.call("INCRBY", KEYS[1], ARGV[1])
+redis.call("HSET", KEYS[1] .. ":meta", "lastBumpStep", ARGV[1])
+return maxId
+`)
+
+// RedisStore 是基于 Redis 的 SegmentStore 实现，每个 bizTag 对应一个
+// INCRBY 计数器 key，计数器的当前值即号段的上界(maxID)。同一个 RedisStore
+// 通常会被 MultiTenantGenerator 里多个 bizTag 共享，所以 Close 做了幂等处理。
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewRedisStore 返回一个连接到 addr 的 RedisStore，实际使用的 key 为
+// keyPrefix+bizTag。
+func NewRedisStore(addr, password string, db int, keyPrefix string) *RedisStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+	return NewRedisStoreWithClient(client, keyPrefix)
+}
+
+// NewRedisStoreWithClient 用一个已经创建好的 *redis.Client 构造 RedisStore，
+// 方便测试时传入指向 miniredis 之类内存实现的客户端。
+func NewRedisStoreWithClient(client *redis.Client, keyPrefix string) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (s *RedisStore) key(bizTag string) string {
+	return s.keyPrefix + bizTag
+}
+
+func (s *RedisStore) FetchAndBump(ctx context.Context, bizTag string, step int32) (int64, error) {
+	maxID, err := bumpScript.Run(ctx, s.client, []string{s.key(bizTag)}, step).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("redis incrby failed: %v", err)
+	}
+	return maxID, nil
+}
+
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *RedisStore) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.client.Close()
+	})
+	return s.closeErr
+}
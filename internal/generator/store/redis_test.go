@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisStore(t *testing.T, keyPrefix string) *RedisStore {
+	t.Helper()
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("启动miniredis失败: %v", err)
+	}
+	t.Cleanup(mr.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	return NewRedisStoreWithClient(client, keyPrefix)
+}
+
+func TestRedisStoreFetchAndBumpIncrementsPerBizTag(t *testing.T) {
+	s := newTestRedisStore(t, "idgen:segment:")
+	ctx := context.Background()
+
+	first, err := s.FetchAndBump(ctx, "order", 1000)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+	if first != 1000 {
+		t.Fatalf("期望第一次分配的maxID为1000，实际为%d", first)
+	}
+
+	second, err := s.FetchAndBump(ctx, "order", 1000)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+	if second != 2000 {
+		t.Fatalf("期望第二次分配的maxID为2000，实际为%d", second)
+	}
+}
+
+func TestRedisStoreFetchAndBumpIsolatesBizTags(t *testing.T) {
+	s := newTestRedisStore(t, "idgen:segment:")
+	ctx := context.Background()
+
+	orderMax, err := s.FetchAndBump(ctx, "order", 100)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+	paymentMax, err := s.FetchAndBump(ctx, "payment", 100)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+
+	if orderMax != 100 || paymentMax != 100 {
+		t.Fatalf("期望不同bizTag各自从100开始计数，实际order=%d payment=%d", orderMax, paymentMax)
+	}
+}
+
+func TestRedisStoreCloseIsIdempotent(t *testing.T) {
+	s := newTestRedisStore(t, "idgen:segment:")
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("第一次Close失败: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("第二次Close应该是幂等的，不应该返回错误: %v", err)
+	}
+}
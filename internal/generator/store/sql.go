@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// SQLDialect 决定 SQLStore 生成的 SQL 语法（占位符风格、是否支持 RETURNING）。
+type SQLDialect int
+
+const (
+	DialectMySQL SQLDialect = iota
+	DialectPostgres
+)
+
+// SQLStore 是基于关系型数据库的 SegmentStore 实现，用一张
+// (biz_tag VARCHAR PRIMARY KEY, max_id BIGINT) 表保存号段。Postgres 下用
+// 一条 UPSERT ... RETURNING 语句原子自增；MySQL 不支持 RETURNING，改用
+// SELECT ... FOR UPDATE 锁行后在事务里手动加减。同一个 SQLStore 通常会被
+// MultiTenantGenerator 里多个 bizTag 共享，所以 Close 做了幂等处理。
+type SQLStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+	table   string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewSQLStore 包装一个已经打开的 *sql.DB，table 为空时默认使用 "segments"。
+func NewSQLStore(db *sql.DB, dialect SQLDialect, table string) *SQLStore {
+	if table == "" {
+		table = "segments"
+	}
+	return &SQLStore{db: db, dialect: dialect, table: table}
+}
+
+func (s *SQLStore) FetchAndBump(ctx context.Context, bizTag string, step int32) (int64, error) {
+	if s.dialect == DialectPostgres {
+		return s.fetchAndBumpPostgres(ctx, bizTag, step)
+	}
+	return s.fetchAndBumpMySQL(ctx, bizTag, step)
+}
+
+func (s *SQLStore) fetchAndBumpPostgres(ctx context.Context, bizTag string, step int32) (int64, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (biz_tag, max_id) VALUES ($1, $2)
+		ON CONFLICT (biz_tag) DO UPDATE SET max_id = %s.max_id + $2
+		RETURNING max_id`, s.table, s.table)
+
+	var maxID int64
+	if err := s.db.QueryRowContext(ctx, query, bizTag, step).Scan(&maxID); err != nil {
+		return 0, fmt.Errorf("postgres upsert returning failed: %v", err)
+	}
+	return maxID, nil
+}
+
+func (s *SQLStore) fetchAndBumpMySQL(ctx context.Context, bizTag string, step int32) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx failed: %v", err)
+	}
+	defer tx.Rollback()
+
+	var maxID int64
+	row := tx.QueryRowContext(ctx, fmt.Sprintf("SELECT max_id FROM %s WHERE biz_tag = ? FOR UPDATE", s.table), bizTag)
+	switch err := row.Scan(&maxID); err {
+	case sql.ErrNoRows:
+		maxID = int64(step)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (biz_tag, max_id) VALUES (?, ?)", s.table), bizTag, maxID); err != nil {
+			return 0, fmt.Errorf("mysql insert failed: %v", err)
+		}
+	case nil:
+		maxID += int64(step)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET max_id = ? WHERE biz_tag = ?", s.table), maxID, bizTag); err != nil {
+			return 0, fmt.Errorf("mysql update failed: %v", err)
+		}
+	default:
+		return 0, fmt.Errorf("mysql select for update failed: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx failed: %v", err)
+	}
+	return maxID, nil
+}
+
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *SQLStore) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.db.Close()
+	})
+	return s.closeErr
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+)
+
+func newTestSQLStore(t *testing.T, dialect SQLDialect) (*SQLStore, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("创建sqlmock失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewSQLStore(db, dialect, "segments"), mock
+}
+
+func TestSQLStoreMySQLInsertsWhenBizTagMissing(t *testing.T) {
+	s, mock := newTestSQLStore(t, DialectMySQL)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT max_id FROM segments WHERE biz_tag = \\? FOR UPDATE").
+		WithArgs("order").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO segments").
+		WithArgs("order", int64(1000)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	maxID, err := s.FetchAndBump(context.Background(), "order", 1000)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+	if maxID != 1000 {
+		t.Fatalf("期望bizTag首次出现时maxID为step本身(1000)，实际为%d", maxID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("未满足的sqlmock期望: %v", err)
+	}
+}
+
+func TestSQLStoreMySQLUpdatesWhenBizTagExists(t *testing.T) {
+	s, mock := newTestSQLStore(t, DialectMySQL)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT max_id FROM segments WHERE biz_tag = \\? FOR UPDATE").
+		WithArgs("order").
+		WillReturnRows(sqlmock.NewRows([]string{"max_id"}).AddRow(int64(5000)))
+	mock.ExpectExec("UPDATE segments SET max_id = \\? WHERE biz_tag = \\?").
+		WithArgs(int64(6000), "order").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	maxID, err := s.FetchAndBump(context.Background(), "order", 1000)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+	if maxID != 6000 {
+		t.Fatalf("期望在已有maxID=5000的基础上加上step=1000得到6000，实际为%d", maxID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("未满足的sqlmock期望: %v", err)
+	}
+}
+
+func TestSQLStoreMySQLRollsBackOnUpdateFailure(t *testing.T) {
+	s, mock := newTestSQLStore(t, DialectMySQL)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT max_id FROM segments WHERE biz_tag = \\? FOR UPDATE").
+		WithArgs("order").
+		WillReturnRows(sqlmock.NewRows([]string{"max_id"}).AddRow(int64(5000)))
+	mock.ExpectExec("UPDATE segments SET max_id = \\? WHERE biz_tag = \\?").
+		WithArgs(int64(6000), "order").
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	if _, err := s.FetchAndBump(context.Background(), "order", 1000); err == nil {
+		t.Fatal("期望UPDATE失败时FetchAndBump返回错误")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("未满足的sqlmock期望: %v", err)
+	}
+}
+
+func TestSQLStorePostgresUpsertReturning(t *testing.T) {
+	s, mock := newTestSQLStore(t, DialectPostgres)
+
+	mock.ExpectQuery("INSERT INTO segments").
+		WithArgs("order", int32(1000)).
+		WillReturnRows(sqlmock.NewRows([]string{"max_id"}).AddRow(int64(1000)))
+
+	maxID, err := s.FetchAndBump(context.Background(), "order", 1000)
+	if err != nil {
+		t.Fatalf("FetchAndBump失败: %v", err)
+	}
+	if maxID != 1000 {
+		t.Fatalf("期望maxID为1000，实际为%d", maxID)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("未满足的sqlmock期望: %v", err)
+	}
+}
+
+func TestSQLStoreCloseIsIdempotent(t *testing.T) {
+	s, _ := newTestSQLStore(t, DialectMySQL)
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("第一次Close失败: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("第二次Close应该是幂等的，不应该返回错误: %v", err)
+	}
+}
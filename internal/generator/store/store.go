@@ -0,0 +1,18 @@
+// Package store abstracts the segment persistence backend used by
+// SegmentIDGenerator so it is not tied to any single database.
+package store
+
+import "context"
+
+// SegmentStore 抽象号段存储后端：每次调用 FetchAndBump 都会把某个 bizTag
+// 对应的 maxId 原子地增加 step，并返回增加后的值作为新号段的上界。
+type SegmentStore interface {
+	// FetchAndBump 为 bizTag 原子地分配一个新号段，返回新号段的上界(maxID)。
+	FetchAndBump(ctx context.Context, bizTag string, step int32) (maxID int64, err error)
+
+	// Ping 检查存储后端当前是否可达，供 /readyz 之类的健康检查使用。
+	Ping(ctx context.Context) error
+
+	// Close 释放存储后端持有的连接等资源。
+	Close() error
+}
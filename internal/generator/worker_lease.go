@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// workerLeaseTTL 是 workers 集合中租约文档的存活时间，节点崩溃后不再续租，
+// 租约会在 TTL 到期后被 Mongo 自动清理，workerID 也就能被其他节点复用。
+const workerLeaseTTL = 30 * time.Second
+
+// maxConsecutiveRenewFailures 是续租允许连续失败的次数，超过后认为租约随时
+// 可能已经被 TTL 回收，必须停止发号，避免另一个节点拿到同一个 workerID 后
+// 两边同时在发同一批ID。
+const maxConsecutiveRenewFailures = 3
+
+// AcquireWorkerID 为 SnowflakeIDGenerator 申请一个 workerID：在 Mongo 的
+// workers 集合里为 [0,maxWorkerID] 中第一个没有存活租约的 _id 插入一条以
+// 主机名标记、带 TTL 的租约文档，并在后台定期续租。返回的 release 函数用于
+// 主动释放租约，调用方通常把它交给 NewSnowflakeIDGeneratorWithRelease；
+// 返回的 leaseLost 在续租连续失败到无法确认租约仍然有效时关闭，调用方应
+// 停止用对应的 workerID 发号。
+func AcquireWorkerID(ctx context.Context, mongoURI string) (workerID int64, release func() error, leaseLost <-chan struct{}, err error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("connect mongodb failed: %v", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("read hostname failed: %v", err)
+	}
+
+	coll := client.Database("test").Collection("workers")
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"expireAt": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}); err != nil {
+		return 0, nil, nil, fmt.Errorf("create ttl index failed: %v", err)
+	}
+
+	workerID = -1
+	for candidate := int64(0); candidate <= maxWorkerID; candidate++ {
+		_, err := coll.InsertOne(ctx, bson.M{
+			"_id":      candidate,
+			"hostname": hostname,
+			"expireAt": time.Now().Add(workerLeaseTTL),
+		})
+		if err == nil {
+			workerID = candidate
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return 0, nil, nil, fmt.Errorf("acquire worker lease failed: %v", err)
+		}
+	}
+
+	if workerID < 0 {
+		return 0, nil, nil, fmt.Errorf("no free workerID in [0,%d]", maxWorkerID)
+	}
+
+	stopRenew := make(chan struct{})
+	lost := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(workerLeaseTTL / 3)
+		defer ticker.Stop()
+		failures := 0
+		for {
+			select {
+			case <-ticker.C:
+				res, err := coll.UpdateByID(context.Background(), workerID, bson.M{
+					"$set": bson.M{"expireAt": time.Now().Add(workerLeaseTTL)},
+				})
+				if err == nil && res.MatchedCount == 0 {
+					// 文档本身已经不在了，大概率已经被 TTL 回收，没有重试的意义，
+					// 直接判定租约丢失。
+					close(lost)
+					return
+				}
+				if err != nil {
+					failures++
+					if failures >= maxConsecutiveRenewFailures {
+						close(lost)
+						return
+					}
+					continue
+				}
+				failures = 0
+			case <-stopRenew:
+				return
+			}
+		}
+	}()
+
+	release = func() error {
+		close(stopRenew)
+		_, delErr := coll.DeleteOne(context.Background(), bson.M{"_id": workerID})
+		if discErr := client.Disconnect(context.Background()); discErr != nil && delErr == nil {
+			delErr = discErr
+		}
+		return delErr
+	}
+
+	return workerID, release, lost, nil
+}
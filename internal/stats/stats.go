@@ -0,0 +1,179 @@
+// Package stats holds the runtime counters the generator package reports
+// through /metrics and periodic JSON snapshot logs.
+package stats
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const maxLatencySamples = 1000
+
+// Stats 维护号段发号器的运行时计数器，所有导出方法都可以被多个 goroutine
+// 并发调用。
+type Stats struct {
+	idsIssued           atomic.Int64
+	segmentLoads        atomic.Int64
+	segmentLoadFailures atomic.Int64
+	switchRetries       atomic.Int64
+
+	utilizationMu  sync.Mutex
+	utilizationPPM map[string]int64 // bizTag -> 当前号段使用率(百万分之一)；多个bizTag共享同一个Stats时（见MultiTenantGenerator）按bizTag分别记录，避免互相覆盖
+
+	latencyMu      sync.Mutex
+	latencySamples []float64 // 最近一批号段加载耗时(ms)，用于计算延迟分位数
+}
+
+func New() *Stats {
+	return &Stats{utilizationPPM: make(map[string]int64)}
+}
+
+// RecordIDIssued 在每次成功发号时调用。
+func (s *Stats) RecordIDIssued() {
+	s.idsIssued.Add(1)
+}
+
+// RecordSegmentLoad 记录一次号段加载的耗时和结果。
+func (s *Stats) RecordSegmentLoad(latency time.Duration, err error) {
+	s.segmentLoads.Add(1)
+	if err != nil {
+		s.segmentLoadFailures.Add(1)
+		return
+	}
+
+	s.latencyMu.Lock()
+	s.latencySamples = append(s.latencySamples, float64(latency.Microseconds())/1000)
+	if len(s.latencySamples) > maxLatencySamples {
+		s.latencySamples = s.latencySamples[len(s.latencySamples)-maxLatencySamples:]
+	}
+	s.latencyMu.Unlock()
+}
+
+// RecordSwitchRetry 在 switchSegment 因号段未就绪而重试时调用。
+func (s *Stats) RecordSwitchRetry() {
+	s.switchRetries.Add(1)
+}
+
+// SetUtilization 更新指定bizTag当前号段的使用率（已用数量/号段总量）。
+func (s *Stats) SetUtilization(bizTag string, used, total int64) {
+	ppm := int64(0)
+	if total > 0 {
+		ppm = int64(float64(used) / float64(total) * 1e6)
+	}
+
+	s.utilizationMu.Lock()
+	s.utilizationPPM[bizTag] = ppm
+	s.utilizationMu.Unlock()
+}
+
+// utilizationSnapshot 返回每个bizTag当前号段使用率的只读快照（0～1）。
+func (s *Stats) utilizationSnapshot() map[string]float64 {
+	s.utilizationMu.Lock()
+	defer s.utilizationMu.Unlock()
+
+	snapshot := make(map[string]float64, len(s.utilizationPPM))
+	for bizTag, ppm := range s.utilizationPPM {
+		snapshot[bizTag] = float64(ppm) / 1e6
+	}
+	return snapshot
+}
+
+func (s *Stats) percentile(p float64) float64 {
+	s.latencyMu.Lock()
+	defer s.latencyMu.Unlock()
+
+	if len(s.latencySamples) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), s.latencySamples...)
+	sort.Float64s(sorted)
+	idx := int(p / 100 * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// Snapshot 是某一时刻所有计数器的只读快照，用于周期性JSON日志。
+type Snapshot struct {
+	IDsIssued           int64              `json:"idsIssued"`
+	SegmentLoads        int64              `json:"segmentLoads"`
+	SegmentLoadFailures int64              `json:"segmentLoadFailures"`
+	SwitchRetries       int64              `json:"switchRetries"`
+	LoadLatencyP50Ms    float64            `json:"loadLatencyP50Ms"`
+	LoadLatencyP99Ms    float64            `json:"loadLatencyP99Ms"`
+	SegmentUtilization  map[string]float64 `json:"segmentUtilization"` // 按bizTag记录，单租户时只有一个key
+}
+
+func (s *Stats) Snapshot() Snapshot {
+	return Snapshot{
+		IDsIssued:           s.idsIssued.Load(),
+		SegmentLoads:        s.segmentLoads.Load(),
+		SegmentLoadFailures: s.segmentLoadFailures.Load(),
+		SwitchRetries:       s.switchRetries.Load(),
+		LoadLatencyP50Ms:    s.percentile(50),
+		LoadLatencyP99Ms:    s.percentile(99),
+		SegmentUtilization:  s.utilizationSnapshot(),
+	}
+}
+
+// Registry 构建一个暴露上述计数器的 Prometheus 注册表，交给 promhttp 渲染
+// GET /metrics。每个指标在抓取时直接读取 Stats 的当前值（计数器读原子字段，
+// 延迟分位数和号段使用率在各自的锁保护下读取），Stats 本身始终
+// 是唯一的数据来源。
+func (s *Stats) Registry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "idgen_ids_issued_total",
+			Help: "Total number of IDs issued.",
+		}, func() float64 { return float64(s.idsIssued.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "idgen_segment_loads_total",
+			Help: "Total number of segment load attempts.",
+		}, func() float64 { return float64(s.segmentLoads.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "idgen_segment_load_failures_total",
+			Help: "Total number of failed segment loads.",
+		}, func() float64 { return float64(s.segmentLoadFailures.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "idgen_switch_retries_total",
+			Help: "Total number of segment switch retries.",
+		}, func() float64 { return float64(s.switchRetries.Load()) }),
+		&utilizationCollector{stats: s},
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "idgen_segment_load_latency_p50_ms",
+			Help: "p50 segment load latency in milliseconds.",
+		}, func() float64 { return s.percentile(50) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "idgen_segment_load_latency_p99_ms",
+			Help: "p99 segment load latency in milliseconds.",
+		}, func() float64 { return s.percentile(99) }),
+	)
+	return reg
+}
+
+var utilizationDesc = prometheus.NewDesc(
+	"idgen_segment_utilization_ratio",
+	"Fraction of the current segment that has been consumed, by bizTag.",
+	[]string{"biz_tag"}, nil,
+)
+
+// utilizationCollector 按bizTag把 Stats.utilizationPPM 暴露成带 biz_tag 标签的
+// Gauge；utilizationPPM 在多租户场景下由多个bizTag共享同一个Stats，单个无标签
+// 的Gauge会被互相覆盖，所以这里不能用 NewGaugeFunc。
+type utilizationCollector struct {
+	stats *Stats
+}
+
+func (c *utilizationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- utilizationDesc
+}
+
+func (c *utilizationCollector) Collect(ch chan<- prometheus.Metric) {
+	for bizTag, ratio := range c.stats.utilizationSnapshot() {
+		ch <- prometheus.MustNewConstMetric(utilizationDesc, prometheus.GaugeValue, ratio, bizTag)
+	}
+}
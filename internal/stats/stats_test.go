@@ -0,0 +1,68 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsPercentile(t *testing.T) {
+	s := New()
+
+	// 1ms,2ms,...,100ms 的号段加载耗时样本
+	for i := 1; i <= 100; i++ {
+		s.RecordSegmentLoad(time.Duration(i)*time.Millisecond, nil)
+	}
+
+	if got := s.percentile(50); got != 50 {
+		t.Errorf("期望p50为50ms，实际为%v", got)
+	}
+	if got := s.percentile(99); got != 99 {
+		t.Errorf("期望p99为99ms，实际为%v", got)
+	}
+	if got := s.percentile(100); got != 100 {
+		t.Errorf("期望p100为100ms，实际为%v", got)
+	}
+}
+
+func TestStatsPercentileNoSamples(t *testing.T) {
+	s := New()
+
+	if got := s.percentile(50); got != 0 {
+		t.Errorf("没有样本时期望百分位为0，实际为%v", got)
+	}
+}
+
+func TestStatsPercentileIgnoresFailedLoads(t *testing.T) {
+	s := New()
+
+	s.RecordSegmentLoad(10*time.Millisecond, nil)
+	s.RecordSegmentLoad(time.Millisecond, errFake)
+
+	if got := s.percentile(50); got != 10 {
+		t.Errorf("失败的加载不应该计入延迟分位数，期望10ms，实际为%v", got)
+	}
+	if got := s.Snapshot().SegmentLoadFailures; got != 1 {
+		t.Errorf("期望SegmentLoadFailures为1，实际为%d", got)
+	}
+}
+
+func TestStatsUtilizationPerBizTagIsolated(t *testing.T) {
+	s := New()
+
+	s.SetUtilization("biz-a", 80, 100)
+	s.SetUtilization("biz-b", 20, 100)
+
+	snapshot := s.utilizationSnapshot()
+	if snapshot["biz-a"] != 0.8 {
+		t.Errorf("期望biz-a使用率为0.8，实际为%v", snapshot["biz-a"])
+	}
+	if snapshot["biz-b"] != 0.2 {
+		t.Errorf("期望biz-b使用率为0.2，实际为%v", snapshot["biz-b"])
+	}
+}
+
+var errFake = fakeErr{}
+
+type fakeErr struct{}
+
+func (fakeErr) Error() string { return "fake error" }